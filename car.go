@@ -0,0 +1,115 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+
+	blocks "github.com/ipfs/go-block-format"
+	cid "github.com/ipfs/go-cid"
+	files "github.com/ipfs/go-ipfs-files"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+
+	car "github.com/ipfs/go-car"
+)
+
+// GetTar walks the UnixFS DAG at cidStr and streams it to w as a tar
+// archive, so large directories can be handed off to another process
+// (or over the network) without ever being fully materialised on disk.
+func (n *Node) GetTar(ctx context.Context, cidStr string, w io.Writer) error {
+	nd, err := n.api.Unixfs().Get(ctx, icorepath.New(cidStr))
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err := writeTarEntry(tw, cidStr, nd); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func writeTarEntry(tw *tar.Writer, path string, nd files.Node) error {
+	switch t := nd.(type) {
+	case files.File:
+		size, err := t.Size()
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name: path,
+			Mode: 0644,
+			Size: size,
+		}); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, t)
+		return err
+	case files.Directory:
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     path + "/",
+			Mode:     0755,
+			Typeflag: tar.TypeDir,
+		}); err != nil {
+			return err
+		}
+		it := t.Entries()
+		for it.Next() {
+			if err := writeTarEntry(tw, path+"/"+it.Name(), it.Node()); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	default:
+		return fmt.Errorf("unsupported unixfs node type at %s", path)
+	}
+}
+
+// ExportCAR writes a CARv1 stream containing roots and everything they
+// transitively reference to w, for side-loading onto another node
+// without a network hop.
+func (n *Node) ExportCAR(ctx context.Context, roots []cid.Cid, w io.Writer) error {
+	return car.WriteCar(ctx, n.ipfsNode.DAG, roots, w)
+}
+
+// ImportCAR reads a CAR stream produced elsewhere (e.g. by ExportCAR),
+// verifies every block against its own CID, and inserts the verified
+// blocks into the local blockstore. It returns the CAR's root CIDs.
+// This is the air-gapped counterpart to the trustless-gateway fetch path:
+// no network access is required, but a tampered stream is rejected all
+// the same.
+func (n *Node) ImportCAR(ctx context.Context, r io.Reader) ([]cid.Cid, error) {
+	reader, err := car.NewCarReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAR: %w", err)
+	}
+
+	for {
+		blk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sum, err := blk.Cid().Prefix().Sum(blk.RawData())
+		if err == nil && !sum.Equals(blk.Cid()) {
+			err = fmt.Errorf("hash mismatch for %s", blk.Cid())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("block failed verification: %w", err)
+		}
+		verified, err := blocks.NewBlockWithCid(blk.RawData(), blk.Cid())
+		if err != nil {
+			return nil, fmt.Errorf("block failed verification: %w", err)
+		}
+
+		if err := n.ipfsNode.Blockstore.Put(verified); err != nil {
+			return nil, err
+		}
+	}
+
+	return reader.Header.Roots, nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	cid "github.com/ipfs/go-cid"
+	iface "github.com/ipfs/interface-go-ipfs-core"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+
+	car "github.com/ipfs/go-car"
+)
+
+// FetchMode selects how Node.Fetch retrieves content that isn't already
+// local.
+type FetchMode int
+
+const (
+	// FetchBitswap retrieves content over libp2p Bitswap only.
+	FetchBitswap FetchMode = iota
+	// FetchHTTPOnly skips Bitswap entirely and only uses the configured
+	// trustless gateways, for networks where only HTTPS egress is
+	// permitted.
+	FetchHTTPOnly
+	// FetchBitswapThenHTTP tries Bitswap first and falls back to the
+	// trustless gateways on timeout or failure.
+	FetchBitswapThenHTTP
+)
+
+// bitswapFetchTimeout bounds how long Fetch waits on Bitswap before
+// falling back to the trustless gateways under FetchBitswapThenHTTP.
+const bitswapFetchTimeout = 15 * time.Second
+
+// Fetch retrieves c according to mode: over Bitswap, over
+// n.trustlessGateways (Options.TrustlessGateways), or Bitswap first with
+// an HTTP fallback on timeout/failure. Every block fetched over HTTP is
+// re-hashed against its claimed CID before it is written to the local
+// blockstore, so a compromised or misbehaving gateway can withhold data
+// but never forge it.
+func (n *Node) Fetch(ctx context.Context, cidStr string, mode FetchMode, opts AddOptions) (icorepath.Resolved, error) {
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cid %q: %w", cidStr, err)
+	}
+
+	if mode != FetchHTTPOnly {
+		bsCtx := ctx
+		var cancel context.CancelFunc
+		if mode == FetchBitswapThenHTTP {
+			bsCtx, cancel = context.WithTimeout(ctx, bitswapFetchTimeout)
+			defer cancel()
+		}
+
+		resolved, err := fetchBitswap(bsCtx, n.api, cidStr, opts)
+		if err == nil {
+			return resolved, nil
+		}
+		if mode == FetchBitswap {
+			return nil, err
+		}
+		log.Printf("Fetch(): bitswap fetch of %s failed (%s), falling back to trustless gateways", cidStr, err)
+	}
+
+	if len(n.trustlessGateways) == 0 {
+		return nil, fmt.Errorf("no trustless gateways configured")
+	}
+
+	if err := fetchTrustlessCAR(ctx, n, n.trustlessGateways, c); err != nil {
+		// Some gateways only serve raw blocks, not CARs - fall back to a
+		// single verified block for CIDs that don't need a DAG walk.
+		blk, blockErr := fetchTrustlessBlock(ctx, n.trustlessGateways, c)
+		if blockErr != nil {
+			return nil, fmt.Errorf("trustless fetch of %s failed: %w; raw-block fallback: %s", cidStr, err, blockErr)
+		}
+		if err := n.ipfsNode.Blockstore.Put(blk); err != nil {
+			return nil, err
+		}
+	}
+
+	resolved := icorepath.IpfsPath(c)
+	if opts.Pin {
+		if err := n.Pin(ctx, resolved, true); err != nil {
+			return nil, fmt.Errorf("failed to pin %s: %w", cidStr, err)
+		}
+	}
+
+	return resolved, nil
+}
+
+// fetchBitswap retrieves cidStr over Bitswap and re-adds it exactly as
+// the legacy fetch() helper does, but returns errors instead of
+// panicking: a Bitswap timeout (the very case Fetch's HTTP fallback
+// exists to handle) must come back as an error, not crash the process.
+func fetchBitswap(ctx context.Context, ipfs iface.CoreAPI, cidStr string, opts AddOptions) (icorepath.Resolved, error) {
+	path := icorepath.New(cidStr)
+
+	content, err := ipfs.Unixfs().Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("could not get contents of CID: %w", err)
+	}
+
+	unixfsOpts, err := opts.unixfsOpts()
+	if err != nil {
+		return nil, fmt.Errorf("invalid add options: %w", err)
+	}
+
+	resolved, err := ipfs.Unixfs().Add(ctx, content, unixfsOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add content: %w", err)
+	}
+
+	return resolved, nil
+}
+
+// fetchTrustlessCAR asks each gateway in turn for a CARv1 stream rooted
+// at c, verifies every block it contains against its own CID as it is
+// read, and inserts the verified blocks into the local blockstore. It
+// stops at the first gateway that serves a fully-verified response.
+func fetchTrustlessCAR(ctx context.Context, n *Node, gateways []string, c cid.Cid) error {
+	var lastErr error
+	for _, gw := range gateways {
+		if err := fetchTrustlessCARFrom(ctx, n, gw, c); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("all gateways failed, last error: %w", lastErr)
+}
+
+func fetchTrustlessCARFrom(ctx context.Context, n *Node, gateway string, c cid.Cid) error {
+	url := fmt.Sprintf("%s/ipfs/%s?format=car", strings.TrimRight(gateway, "/"), c.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.ipld.car")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gateway %s returned %s", gateway, resp.Status)
+	}
+
+	reader, err := car.NewCarReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("invalid CAR from %s: %w", gateway, err)
+	}
+
+	var blockCount int
+	for {
+		blk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading CAR from %s: %w", gateway, err)
+		}
+
+		// Re-hash the payload with the CID's own prefix and reject it if
+		// the digest doesn't match blk.Cid(), so a gateway can withhold
+		// blocks but can't substitute forged ones.
+		sum, err := blk.Cid().Prefix().Sum(blk.RawData())
+		if err == nil && !sum.Equals(blk.Cid()) {
+			err = fmt.Errorf("hash mismatch for %s", blk.Cid())
+		}
+		if err != nil {
+			return fmt.Errorf("block from %s failed verification: %w", gateway, err)
+		}
+		verified, err := blocks.NewBlockWithCid(blk.RawData(), blk.Cid())
+		if err != nil {
+			return fmt.Errorf("block from %s failed verification: %w", gateway, err)
+		}
+
+		if err := n.ipfsNode.Blockstore.Put(verified); err != nil {
+			return err
+		}
+		blockCount++
+	}
+
+	if blockCount == 0 {
+		return fmt.Errorf("gateway %s returned an empty CAR", gateway)
+	}
+
+	// Every block re-hashes correctly against its own CID, but that only
+	// proves the CAR is internally self-consistent - not that it has
+	// anything to do with c. Require c itself to be covered by the
+	// response before trusting it.
+	for _, root := range reader.Header.Roots {
+		if root.Equals(c) {
+			return nil
+		}
+	}
+
+	has, err := n.ipfsNode.Blockstore.Has(c)
+	if err != nil {
+		return fmt.Errorf("checking blockstore for %s: %w", c, err)
+	}
+	if !has {
+		return fmt.Errorf("gateway %s returned a CAR that never mentions requested cid %s", gateway, c)
+	}
+
+	return nil
+}
+
+// fetchTrustlessBlock fetches a single raw block (no DAG walking) from
+// the first gateway willing to serve it, verifying it against c before
+// returning it.
+func fetchTrustlessBlock(ctx context.Context, gateways []string, c cid.Cid) (blocks.Block, error) {
+	var lastErr error
+	for _, gw := range gateways {
+		url := fmt.Sprintf("%s/ipfs/%s?format=raw", strings.TrimRight(gw, "/"), c.String())
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("gateway %s returned %s", gw, resp.Status)
+			continue
+		}
+
+		sum, err := c.Prefix().Sum(data)
+		if err == nil && !sum.Equals(c) {
+			err = fmt.Errorf("hash mismatch for %s", c)
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("block from %s failed verification: %w", gw, err)
+			continue
+		}
+		blk, err := blocks.NewBlockWithCid(data, c)
+		if err != nil {
+			lastErr = fmt.Errorf("block from %s failed verification: %w", gw, err)
+			continue
+		}
+
+		return blk, nil
+	}
+
+	return nil, fmt.Errorf("all gateways failed, last error: %w", lastErr)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/core/corehttp"
+)
+
+// GatewayOptions configures the embedded read (and optionally write)
+// HTTP gateway started by Node.ServeGateway.
+type GatewayOptions struct {
+	// Addrs are the listen addresses for the gateway, e.g.
+	// []string{"127.0.0.1:8080"}.
+	Addrs []string
+	// Writable allows PUT/POST/DELETE against /ipfs/... paths.
+	Writable bool
+	// CORS, when non-empty, is sent back as Access-Control-Allow-Origin
+	// for every gateway request.
+	CORS string
+	// Subdomain serves content under <cid>.ipfs.<host> instead of
+	// <host>/ipfs/<cid>, as browsers require for origin-isolated dapps.
+	Subdomain bool
+	// PathRedirects honours a UnixFS directory's _redirects file, the
+	// convention used to serve single-page apps straight out of IPFS.
+	PathRedirects bool
+}
+
+// APIOptions configures the embedded Kubo-compatible /api/v0 command RPC
+// started by Node.ServeAPI.
+type APIOptions struct {
+	// Addrs are the listen addresses for the RPC API, e.g.
+	// []string{"127.0.0.1:5001"}.
+	Addrs []string
+}
+
+// httpServer tracks the net.Listeners backing a running corehttp server
+// so Node can report what it actually bound to (useful when an addr of
+// "127.0.0.1:0" asked the OS to pick a free port).
+type httpServer struct {
+	listeners []net.Listener
+}
+
+func (h *httpServer) addrs() []string {
+	addrs := make([]string, len(h.listeners))
+	for i, l := range h.listeners {
+		addrs[i] = l.Addr().String()
+	}
+	return addrs
+}
+
+func listen(addrs []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// ServeGateway starts the gateway on opts.Addrs and returns once every
+// listener is bound; the servers themselves keep running in the
+// background until Close is called. Call GatewayAddrs afterwards to see
+// what they actually bound to.
+func (n *Node) ServeGateway(opts GatewayOptions) error {
+	if n.gateway != nil {
+		return fmt.Errorf("gateway is already serving on %v", n.gateway.addrs())
+	}
+
+	listeners, err := listen(opts.Addrs)
+	if err != nil {
+		return err
+	}
+
+	serveOpts := []corehttp.ServeOption{}
+	if opts.Subdomain {
+		serveOpts = append(serveOpts, corehttp.HostnameOption())
+	}
+	serveOpts = append(serveOpts, corehttp.GatewayOption(opts.Writable, "/ipfs", "/ipns"))
+	if opts.PathRedirects {
+		serveOpts = append(serveOpts, corehttp.RedirectOption("", ""))
+	}
+	if opts.CORS != "" {
+		serveOpts = append(serveOpts, corehttp.CORSOption(opts.CORS))
+	}
+
+	n.gateway = &httpServer{listeners: listeners}
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			_ = corehttp.Serve(n.ipfsNode, l, serveOpts...)
+		}(l)
+	}
+
+	return nil
+}
+
+// ServeAPI starts the Kubo-compatible /api/v0 command RPC on opts.Addrs,
+// so existing tooling (the `ipfs` CLI, go-ipfs-api / kubo-rpc-client) can
+// drive this embedded node exactly as it would a standalone daemon.
+func (n *Node) ServeAPI(opts APIOptions) error {
+	if n.rpcAPI != nil {
+		return fmt.Errorf("API is already serving on %v", n.rpcAPI.addrs())
+	}
+
+	listeners, err := listen(opts.Addrs)
+	if err != nil {
+		return err
+	}
+
+	cctx := cmds.Context{
+		ConfigRoot: n.ipfsNode.Repo.Path(),
+		ConstructNode: func() (*core.IpfsNode, error) {
+			return n.ipfsNode, nil
+		},
+	}
+
+	serveOpts := []corehttp.ServeOption{
+		corehttp.CommandsOption(cctx),
+	}
+
+	n.rpcAPI = &httpServer{listeners: listeners}
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			_ = corehttp.Serve(n.ipfsNode, l, serveOpts...)
+		}(l)
+	}
+
+	return nil
+}
+
+// GatewayAddrs returns the addresses the gateway ended up bound to, once
+// ServeGateway has been called.
+func (n *Node) GatewayAddrs() []string {
+	if n.gateway == nil {
+		return nil
+	}
+	return n.gateway.addrs()
+}
+
+// APIAddrs returns the addresses the /api/v0 RPC ended up bound to, once
+// ServeAPI has been called.
+func (n *Node) APIAddrs() []string {
+	if n.rpcAPI == nil {
+		return nil
+	}
+	return n.rpcAPI.addrs()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	iface "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/ipfs/interface-go-ipfs-core/options"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+)
+
+// Publish republishes cidPath under the IPNS key keyName, so it can be
+// resolved by its stable /ipns/<peer-id-or-key> name instead of the
+// immutable CID. An empty keyName publishes under the node's own
+// (self) key. validity controls how long the resulting record is valid
+// for before it must be republished; zero keeps the Name.Publish default.
+func (n *Node) Publish(ctx context.Context, cidPath icorepath.Path, keyName string, validity time.Duration) (string, error) {
+	opts := []options.NamePublishOption{}
+	if keyName != "" {
+		opts = append(opts, options.Name.Key(keyName))
+	}
+	if validity > 0 {
+		opts = append(opts, options.Name.ValidTime(validity))
+	}
+
+	entry, err := n.api.Name().Publish(ctx, cidPath, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.Name(), nil
+}
+
+// Resolve follows an IPNS name (e.g. "/ipns/<peer-id>" or a DNSLink
+// domain) down to the immutable path it currently points at.
+func (n *Node) Resolve(ctx context.Context, ipnsName string) (icorepath.Path, error) {
+	return n.api.Name().Resolve(ctx, ipnsName)
+}
+
+// GenerateKey creates a new IPNS signing key under keyName.
+func (n *Node) GenerateKey(ctx context.Context, keyName string) (iface.Key, error) {
+	return n.api.Key().Generate(ctx, keyName)
+}
+
+// ListKeys returns every IPNS key this node holds, including "self".
+func (n *Node) ListKeys(ctx context.Context) ([]iface.Key, error) {
+	return n.api.Key().List(ctx)
+}
+
+// RenameKey renames an IPNS key, optionally overwriting an existing key
+// of the target name.
+func (n *Node) RenameKey(ctx context.Context, oldName, newName string, force bool) (iface.Key, bool, error) {
+	return n.api.Key().Rename(ctx, oldName, newName, options.Key.Force(force))
+}
+
+// RemoveKey deletes an IPNS key by name.
+func (n *Node) RemoveKey(ctx context.Context, keyName string) (iface.Key, error) {
+	return n.api.Key().Remove(ctx, keyName)
+}
@@ -38,8 +38,14 @@ func main() {
 	// create a temporary directory for the instance to store content (eg. tempPath = "/tmp1278371/")
 	tempPath := createTempDir("tmp") + "/"
 
-	// create() - initalises an embedded IPFS instance w/ a given context
-	ipfs := create(ctx)
+	// New() - initialises an embedded IPFS node w/ a given context
+	n, err := New(ctx, Options{Online: true})
+	if err != nil {
+		panic(fmt.Errorf("failed to start embedded IPFS node: %s", err))
+	}
+	defer n.Close()
+
+	ipfs := n.CoreAPI()
 
 	/// TEST 1 (must work offline): PASSED
 	/** desc:
@@ -66,7 +72,7 @@ func main() {
 
 	// add() - adds any content to IPFS instance
 	// QmdjWNJPGBWL8Vs5M6TFNatphsgTpiPRHXjWt7M5TsDXje - a random picture from pinata.cloud
-	fetchedFileObj := fetch(ipfs, ctx, "QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN")
+	fetchedFileObj := fetch(ipfs, ctx, "QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN", AddOptions{Pin: true})
 
 	// get() - get any given content from IPFS instance
 	fetchedfile := get(ipfs, ctx, fetchedFileObj.Cid().String())
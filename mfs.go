@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	files "github.com/ipfs/go-ipfs-files"
+	ipld "github.com/ipfs/go-ipld-format"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+
+	"github.com/ipfs/go-ipfs/mfs"
+)
+
+// MFSStat summarises an MFS entry, mirroring the handful of fields
+// callers typically need from `ipfs files stat`.
+type MFSStat struct {
+	Cid  string
+	Size uint64
+	Type string
+}
+
+// Mkdir creates dir (and, if parents is true, any missing ancestors)
+// inside the node's mutable file system root.
+func (n *Node) Mkdir(dir string, parents bool) error {
+	return mfs.Mkdir(n.ipfsNode.FilesRoot, dir, mfs.MkdirOpts{
+		Mkparents: parents,
+		Flush:     true,
+	})
+}
+
+// Write adds content to the DAG and links the result into the MFS root
+// at path. It returns an error if an entry already exists at path;
+// nothing is replaced.
+func (n *Node) Write(ctx context.Context, path string, content files.Node, opts AddOptions) error {
+	unixfsOpts, err := opts.unixfsOpts()
+	if err != nil {
+		return fmt.Errorf("invalid add options: %w", err)
+	}
+
+	added, err := n.api.Unixfs().Add(ctx, content, unixfsOpts...)
+	if err != nil {
+		return err
+	}
+
+	nd, err := n.api.Dag().Get(ctx, added.Cid())
+	if err != nil {
+		return err
+	}
+
+	return mfs.PutNode(n.ipfsNode.FilesRoot, path, nd)
+}
+
+// Cp copies whatever is already in the DAG at src (an /ipfs/... or
+// /ipns/... path) into the MFS root at dst, without re-fetching or
+// re-adding it.
+func (n *Node) Cp(ctx context.Context, src icorepath.Path, dst string) error {
+	nd, err := n.api.ResolveNode(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	return mfs.PutNode(n.ipfsNode.FilesRoot, dst, nd)
+}
+
+// Stat reports the CID, type and size of the MFS entry at path.
+func (n *Node) Stat(ctx context.Context, path string) (MFSStat, error) {
+	fsn, err := mfs.Lookup(n.ipfsNode.FilesRoot, path)
+	if err != nil {
+		return MFSStat{}, err
+	}
+
+	nd, err := fsn.GetNode()
+	if err != nil {
+		return MFSStat{}, err
+	}
+
+	size, err := nd.Size()
+	if err != nil {
+		return MFSStat{}, err
+	}
+
+	typ := "file"
+	if _, ok := fsn.(*mfs.Directory); ok {
+		typ = "directory"
+	}
+
+	return MFSStat{Cid: nd.Cid().String(), Size: size, Type: typ}, nil
+}
+
+// Flush persists every change made under path (or the whole root, for
+// "/") and returns the flushed node at path; its Cid() is the new CID
+// of that path.
+func (n *Node) Flush(ctx context.Context, path string) (ipld.Node, error) {
+	return mfs.FlushPath(ctx, n.ipfsNode.FilesRoot, path)
+}
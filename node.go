@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cid "github.com/ipfs/go-cid"
+	config "github.com/ipfs/go-ipfs-config"
+	"github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/core/coreapi"
+	"github.com/ipfs/go-ipfs/core/node"
+	"github.com/ipfs/go-ipfs/core/node/libp2p" // This package is needed so that all the preloaded plugins are loaded automatically
+	"github.com/ipfs/go-ipfs/plugin/loader"
+	"github.com/ipfs/go-ipfs/repo/fsrepo"
+	icore "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// RoutingMode selects how a Node participates in content/peer routing.
+type RoutingMode int
+
+const (
+	// RoutingDHTClient only queries the DHT for records; it never serves
+	// them to other peers. This is the lightest-weight option and the
+	// default.
+	RoutingDHTClient RoutingMode = iota
+	// RoutingDHTServer runs a full DHT node that both queries and serves
+	// records for other peers on the network.
+	RoutingDHTServer
+	// RoutingNone disables DHT routing entirely.
+	RoutingNone
+)
+
+// Options configures a Node created with New. The zero value is usable:
+// it creates an offline, DHT-client node backed by a repo under
+// os.TempDir(), which is reused if it already exists and is left on disk
+// by Close. Set Online to true to have it reach the network.
+type Options struct {
+	// RepoPath is the on-disk location of the IPFS repo. Defaults to
+	// filepath.Join(os.TempDir(), "embedded-ipfs") when empty.
+	RepoPath string
+	// KeySize is the RSA key size (in bits) used to generate the repo's
+	// identity when it does not already exist. Defaults to 2048.
+	KeySize int
+	// Routing selects the DHT routing mode. Defaults to RoutingDHTClient.
+	Routing RoutingMode
+	// Online controls whether the node dials out to the network. The
+	// zero value (false) gives a fully local, filesystem-only node; set
+	// true to reach the network over Bitswap/DHT/PubSub.
+	Online bool
+	// SwarmAddrs overrides Addresses.Swarm in the repo config, letting
+	// callers choose which TCP/QUIC ports and IPv4/IPv6 addresses the
+	// swarm listens on (e.g. "/ip4/0.0.0.0/tcp/4001",
+	// "/ip4/0.0.0.0/udp/4001/quic"). Left empty, the defaults baked into
+	// config.Init are used.
+	SwarmAddrs []string
+	// Experimental enables the go-ipfs experimental feature set
+	// (filestore, urlstore, directory sharding, libp2p stream mounting,
+	// the p2p HTTP proxy and strategic providing).
+	Experimental bool
+	// BootstrapPeers overrides the bootstrap peer list used when the repo
+	// is first initialised. Left empty, the defaults baked into
+	// config.Init are used.
+	BootstrapPeers []string
+	// PubSub enables the libp2p pubsub subsystem, required for Node's
+	// PubSub() helpers. DHT-rendezvous peer discovery (AdvertiseNamespace,
+	// DiscoverPeers) goes through the node's router and does not need it.
+	PubSub bool
+	// TrustlessGateways are the gateway base URLs (e.g.
+	// "https://ipfs.io") Node.Fetch falls back to, in order, when asked
+	// to use FetchBitswapThenHTTP or FetchHTTPOnly.
+	TrustlessGateways []string
+}
+
+func (o Options) withDefaults() Options {
+	if o.RepoPath == "" {
+		o.RepoPath = filepath.Join(os.TempDir(), "embedded-ipfs")
+	}
+	if o.KeySize == 0 {
+		o.KeySize = 2048
+	}
+	return o
+}
+
+func (o Options) routingOption() (libp2p.RoutingOption, error) {
+	switch o.Routing {
+	case RoutingDHTClient:
+		return libp2p.DHTClientOption, nil
+	case RoutingDHTServer:
+		return libp2p.DHTOption, nil
+	case RoutingNone:
+		return libp2p.NilRouterOption, nil
+	default:
+		return nil, fmt.Errorf("embedded: unknown routing mode %d", o.Routing)
+	}
+}
+
+// Node is a long-lived embedded IPFS node. Construct one with New and call
+// Close once it is no longer needed to release the repo lock and shut down
+// its background goroutines.
+type Node struct {
+	ipfsNode *core.IpfsNode
+	api      icore.CoreAPI
+
+	gateway           *httpServer
+	rpcAPI            *httpServer
+	trustlessGateways []string
+}
+
+// New sets up the go-ipfs plugins, initialises (or reuses) a repo at
+// opts.RepoPath and spawns an embedded IpfsNode from it. Unlike the
+// previous one-shot create() helper, New never panics: every failure is
+// returned as an error so callers can decide how to react.
+func New(ctx context.Context, opts Options) (*Node, error) {
+	opts = opts.withDefaults()
+
+	if err := setupPlugins(""); err != nil {
+		return nil, fmt.Errorf("failed to setup plugins: %w", err)
+	}
+
+	repoPath, err := createRepo(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repo: %w", err)
+	}
+
+	if len(opts.SwarmAddrs) > 0 {
+		if err := setSwarmAddrs(repoPath, opts.SwarmAddrs); err != nil {
+			return nil, fmt.Errorf("failed to set swarm addresses: %w", err)
+		}
+	}
+
+	repo, err := fsrepo.Open(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repo: %w", err)
+	}
+
+	routingOpt, err := opts.routingOption()
+	if err != nil {
+		return nil, err
+	}
+
+	buildCfg := &node.BuildCfg{
+		Online:  opts.Online,
+		Routing: routingOpt,
+		Repo:    repo,
+		ExtraOpts: map[string]bool{
+			"pubsub": opts.PubSub,
+		},
+	}
+
+	ipfsNode, err := core.NewNode(ctx, buildCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IPFS node: %w", err)
+	}
+
+	api, err := coreapi.NewCoreAPI(ipfsNode)
+	if err != nil {
+		ipfsNode.Close()
+		return nil, fmt.Errorf("failed to build core API: %w", err)
+	}
+
+	return &Node{ipfsNode: ipfsNode, api: api, trustlessGateways: opts.TrustlessGateways}, nil
+}
+
+// setSwarmAddrs opens the repo just long enough to overwrite its
+// Addresses.Swarm config key, then closes it again so the caller can open
+// it for real. This lets New() reconfigure listen addresses on both
+// freshly-initialised and pre-existing repos.
+func setSwarmAddrs(repoPath string, addrs []string) error {
+	repo, err := fsrepo.Open(repoPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return repo.SetConfigKey("Addresses.Swarm", addrs)
+}
+
+// Advertise announces to the DHT that this node can provide c, so that
+// peers performing content routing can find it without a direct
+// connection or a shared bootstrap list. The routing mode is not checked
+// here: the call goes to whichever router the node was built with, and
+// any error that router returns (for example on an offline node) is
+// passed back.
+func (n *Node) Advertise(ctx context.Context, c cid.Cid) error {
+	return n.ipfsNode.Routing.Provide(ctx, c, true)
+}
+
+// CoreAPI returns the underlying interface-go-ipfs-core API, for callers
+// that need direct access beyond the helpers in this package.
+func (n *Node) CoreAPI() icore.CoreAPI {
+	return n.api
+}
+
+// Peer returns this node's own peer ID and listen/observed addresses.
+func (n *Node) Peer() peer.AddrInfo {
+	return peer.AddrInfo{
+		ID:    n.ipfsNode.Identity,
+		Addrs: n.ipfsNode.PeerHost.Addrs(),
+	}
+}
+
+// Close shuts down the IpfsNode (which in turn closes the repo and all
+// goroutines it owns: DHT, bitswap, swarm) along with any gateway or
+// RPC API listeners started on it.
+func (n *Node) Close() error {
+	for _, srv := range []*httpServer{n.gateway, n.rpcAPI} {
+		if srv == nil {
+			continue
+		}
+		for _, l := range srv.listeners {
+			l.Close()
+		}
+	}
+
+	return n.ipfsNode.Close()
+}
+
+func setupPlugins(externalPluginsPath string) error {
+	// Load any external plugins if available on externalPluginsPath
+	plugins, err := loader.NewPluginLoader(filepath.Join(externalPluginsPath, "plugins"))
+	if err != nil {
+		return fmt.Errorf("error loading plugins: %s", err)
+	}
+
+	// Load preloaded and external plugins
+	if err := plugins.Initialize(); err != nil {
+		return fmt.Errorf("error initializing plugins: %s", err)
+	}
+
+	if err := plugins.Inject(); err != nil {
+		return fmt.Errorf("error initializing plugins: %s", err)
+	}
+
+	return nil
+}
+
+// creates the repo using a config for the ipfs instance
+func createRepo(opts Options) (string, error) {
+	if _, err := os.Stat(opts.RepoPath); err == nil {
+		return opts.RepoPath, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	dirPermissions := os.FileMode(0777)
+	if err := os.MkdirAll(opts.RepoPath, dirPermissions); err != nil {
+		return "", err
+	}
+
+	// Create a config with default options and the requested key size
+	cfg, err := config.Init(ioutil.Discard, opts.KeySize)
+	if err != nil {
+		return "", err
+	}
+
+	if len(opts.BootstrapPeers) > 0 {
+		peers, err := config.ParseBootstrapPeers(opts.BootstrapPeers)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse bootstrap peers: %w", err)
+		}
+		cfg.SetBootstrapPeers(peers)
+	}
+
+	if opts.Experimental {
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#ipfs-filestore
+		cfg.Experimental.FilestoreEnabled = true
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#ipfs-urlstore
+		cfg.Experimental.UrlstoreEnabled = true
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#directory-sharding--hamt
+		cfg.Experimental.ShardingEnabled = true
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#ipfs-p2p
+		cfg.Experimental.Libp2pStreamMounting = true
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#p2p-http-proxy
+		cfg.Experimental.P2pHttpProxy = true
+		// https://github.com/ipfs/go-ipfs/blob/master/docs/experimental-features.md#strategic-providing
+		cfg.Experimental.StrategicProviding = true
+	}
+
+	if err := fsrepo.Init(opts.RepoPath, cfg); err != nil {
+		return "", fmt.Errorf("failed to init repo: %w", err)
+	}
+
+	return opts.RepoPath, nil
+}
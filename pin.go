@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	iface "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/ipfs/interface-go-ipfs-core/options"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+	mh "github.com/multiformats/go-multihash"
+
+	"github.com/ipfs/go-ipfs/core/corerepo"
+)
+
+// AddOptions controls how content added via add()/fetch() is chunked,
+// hashed and whether it is pinned, mirroring the options.Unixfs.* set
+// used by interface-go-ipfs-core.
+type AddOptions struct {
+	// CidVersion selects the CID version (0 or 1) for the resulting root.
+	// Zero value defers to the Unixfs.Add default (CIDv0).
+	CidVersion int
+	// RawLeaves stores leaf nodes as raw blocks instead of wrapping them
+	// in a unixfs protobuf.
+	RawLeaves bool
+	// Chunker selects the chunking algorithm, e.g. "size-262144" or
+	// "rabin-min-avg-max". Empty keeps the Unixfs.Add default.
+	Chunker string
+	// HashFun is a multihash name (e.g. "sha2-256", "blake2b-256"). Empty
+	// keeps the Unixfs.Add default.
+	HashFun string
+	// Pin recursively pins the added root so it survives GC.
+	Pin bool
+}
+
+func (o AddOptions) unixfsOpts() ([]options.UnixfsAddOption, error) {
+	opts := []options.UnixfsAddOption{options.Unixfs.Pin(o.Pin)}
+
+	if o.CidVersion != 0 {
+		opts = append(opts, options.Unixfs.CidVersion(o.CidVersion))
+	}
+	if o.RawLeaves {
+		opts = append(opts, options.Unixfs.RawLeaves(true))
+	}
+	if o.Chunker != "" {
+		opts = append(opts, options.Unixfs.Chunker(o.Chunker))
+	}
+	if o.HashFun != "" {
+		code, ok := mh.Names[o.HashFun]
+		if !ok {
+			return nil, fmt.Errorf("unknown hash function %q", o.HashFun)
+		}
+		opts = append(opts, options.Unixfs.Hash(code))
+	}
+
+	return opts, nil
+}
+
+// Pin recursively (or, if recursive is false, directly) pins p so it
+// survives GC.
+func (n *Node) Pin(ctx context.Context, p icorepath.Path, recursive bool) error {
+	return n.api.Pin().Add(ctx, p, options.Pin.Recursive(recursive))
+}
+
+// Unpin removes a previously-added pin for p.
+func (n *Node) Unpin(ctx context.Context, p icorepath.Path, recursive bool) error {
+	return n.api.Pin().Rm(ctx, p, options.Pin.RmRecursive(recursive))
+}
+
+// Pins lists every pin this node currently holds.
+func (n *Node) Pins(ctx context.Context) ([]iface.Pin, error) {
+	pins, err := n.api.Pin().Ls(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []iface.Pin
+	for p := range pins {
+		if p.Err() != nil {
+			return nil, p.Err()
+		}
+		out = append(out, p)
+	}
+
+	return out, nil
+}
+
+// GC runs garbage collection, reclaiming any blocks that are neither
+// pinned nor part of the MFS root.
+func (n *Node) GC(ctx context.Context) error {
+	return corerepo.GarbageCollect(n.ipfsNode, ctx)
+}
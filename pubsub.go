@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	iface "github.com/ipfs/interface-go-ipfs-core"
+	"github.com/ipfs/interface-go-ipfs-core/options"
+	"github.com/libp2p/go-libp2p-core/peer"
+	discovery "github.com/libp2p/go-libp2p-discovery"
+)
+
+// PubSub returns the underlying PubSub API. The node must have been
+// started with Options.PubSub set, otherwise every call on it fails.
+func (n *Node) PubSub() iface.PubSubAPI {
+	return n.api.PubSub()
+}
+
+// PublishTopic sends data to every subscriber of topic.
+func (n *Node) PublishTopic(ctx context.Context, topic string, data []byte) error {
+	return n.PubSub().Publish(ctx, topic, data)
+}
+
+// Subscribe joins topic and returns a subscription; cancel it (or its
+// context) to leave the topic again.
+func (n *Node) Subscribe(ctx context.Context, topic string) (iface.PubSubSubscription, error) {
+	return n.PubSub().Subscribe(ctx, topic)
+}
+
+// Peers lists the peers this node is currently connected to on topic.
+func (n *Node) Peers(ctx context.Context, topic string) ([]peer.ID, error) {
+	return n.PubSub().Peers(ctx, options.PubSub.Topic(topic))
+}
+
+// ListTopics lists every topic this node is currently subscribed to.
+func (n *Node) ListTopics(ctx context.Context) ([]string, error) {
+	return n.PubSub().Ls(ctx)
+}
+
+// AdvertiseNamespace announces this node under the rendezvous namespace ns via
+// the DHT, so other embedded nodes can find it with DiscoverPeers
+// without needing a shared bootstrap list.
+func (n *Node) AdvertiseNamespace(ctx context.Context, ns string) error {
+	disc := discovery.NewRoutingDiscovery(n.ipfsNode.Routing)
+	_, err := disc.Advertise(ctx, ns)
+	return err
+}
+
+// DiscoverPeers finds peers that have advertised themselves under the
+// rendezvous namespace ns via AdvertiseNamespace.
+func (n *Node) DiscoverPeers(ctx context.Context, ns string) (<-chan peer.AddrInfo, error) {
+	disc := discovery.NewRoutingDiscovery(n.ipfsNode.Routing)
+	return disc.FindPeers(ctx, ns)
+}